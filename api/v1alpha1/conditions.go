@@ -0,0 +1,12 @@
+package v1alpha1
+
+// ConditionVerifying is True while the state machine (see
+// controllers/statemachine.Run) is waiting for confirmation that a
+// completed local sync has actually reached its peers, e.g. for movers
+// that report local completion before remote peers have caught up.
+const ConditionVerifying = "Verifying"
+
+// SynchronizingReasonVerifying is set alongside ConditionVerifying above,
+// and alongside ConditionSynchronizing while it's transitioning through
+// verifyingState.
+const SynchronizingReasonVerifying = "Verifying"
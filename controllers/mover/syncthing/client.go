@@ -0,0 +1,202 @@
+package syncthing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// defaultRequestTimeout bounds Client.Do calls whose context carries no
+// deadline of its own; callers of long-poll endpoints (e.g. /rest/events)
+// should pass a context with a longer deadline.
+const defaultRequestTimeout = 10 * time.Second
+
+// APIError is returned for a non-2xx response from the Syncthing API,
+// decoded from the `{"error":"..."}` body Syncthing sends on 4xx/5xx.
+type APIError struct {
+	Status  int
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("syncthing API returned %d: %s", e.Status, e.Message)
+}
+
+// RetryPolicy controls how many times, and how far apart, Client.Do retries
+// a request that failed with a network error or a 5xx response.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// defaultRetryPolicy gives transient failures (a restarting Syncthing pod,
+// a dropped connection) a few jittered retries before giving up.
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+}
+
+// Client is a context-aware REST client for a single Syncthing instance's
+// API. Every request it makes is bound to a context, so that a canceled
+// reconcile or a shutting-down mover can actually interrupt an in-flight
+// request.
+type Client struct {
+	api    *APIConfig
+	logger logr.Logger
+	retry  RetryPolicy
+}
+
+// NewClient returns a Client for the given APIConfig, retrying transient
+// failures per defaultRetryPolicy.
+func NewClient(api *APIConfig, logger logr.Logger) *Client {
+	return &Client{api: api, logger: logger, retry: defaultRetryPolicy}
+}
+
+// Do issues method against path on the Syncthing API, JSON-encoding body
+// (if non-nil) as the request payload and JSON-decoding the response into
+// out (if non-nil). ctx governs cancellation of the whole call, including
+// any retries; if ctx has no deadline, the call is bounded by
+// defaultRequestTimeout.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+
+	fullURL := c.api.APIURL + path
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < c.attempts(); attempt++ {
+		if attempt > 0 {
+			delay := c.backoff(attempt)
+			c.logger.V(6).Info("Retrying Syncthing API request", "method", method, "path", path, "attempt", attempt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		lastErr = c.doOnce(ctx, method, fullURL, jsonBody, out)
+		if lastErr == nil || !isRetryable(lastErr) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+func (c *Client) doOnce(ctx context.Context, method, reqURL string, jsonBody []byte, out interface{}) error {
+	var reqBody io.Reader
+	if jsonBody != nil {
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, reqBody)
+	if err != nil {
+		return err
+	}
+
+	headers, err := c.api.Headers()
+	if err != nil {
+		return err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	c.logger.V(6).Info("Syncthing API request", "method", method, "url", reqURL)
+
+	resp, err := c.api.BuildOrUseExistingTLSClient().Do(req)
+	if err != nil {
+		return &retryableError{err}
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	c.logger.V(6).Info("Syncthing API response", "method", method, "url", reqURL,
+		"status", resp.StatusCode, "bytes", len(data))
+
+	if resp.StatusCode/100 != 2 {
+		apiErr := &APIError{Status: resp.StatusCode}
+		var decoded struct {
+			Error string `json:"error"`
+		}
+		if jsonErr := json.Unmarshal(data, &decoded); jsonErr == nil {
+			apiErr.Message = decoded.Error
+		}
+		if resp.StatusCode >= 500 {
+			return &retryableError{apiErr}
+		}
+		return apiErr
+	}
+
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// retryableError marks an error as safe to retry (network failures and
+// 5xx responses) without altering how it prints or unwraps.
+type retryableError struct {
+	err error
+}
+
+func (r *retryableError) Error() string { return r.err.Error() }
+func (r *retryableError) Unwrap() error { return r.err }
+
+func isRetryable(err error) bool {
+	var re *retryableError
+	return errors.As(err, &re)
+}
+
+func (c *Client) attempts() int {
+	if c.retry.MaxAttempts <= 0 {
+		return 1
+	}
+	return c.retry.MaxAttempts
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	base := c.retry.BaseDelay
+	if base <= 0 {
+		base = defaultRetryPolicy.BaseDelay
+	}
+	maxDelay := c.retry.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryPolicy.MaxDelay
+	}
+
+	d := base * time.Duration(uint(1)<<uint(attempt-1))
+	if d > maxDelay {
+		d = maxDelay
+	}
+	return jitter(d)
+}
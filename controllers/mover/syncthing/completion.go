@@ -0,0 +1,112 @@
+package syncthing
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+// FolderCompletion describes how far along a remote device is in receiving
+// the local instance's view of a folder, as reported by
+// GET /rest/db/completion.
+type FolderCompletion struct {
+	Completion  float64 `json:"completion"`
+	GlobalBytes int64   `json:"globalBytes"`
+	NeedBytes   int64   `json:"needBytes"`
+	GlobalItems int32   `json:"globalItems"`
+	NeedItems   int32   `json:"needItems"`
+	NeedDeletes int32   `json:"needDeletes"`
+	Sequence    int64   `json:"sequence"`
+}
+
+// FetchFolderCompletion fetches the completion status of folderID as seen
+// from deviceID's perspective.
+func (st *Syncthing) FetchFolderCompletion(ctx context.Context, deviceID, folderID string) (FolderCompletion, error) {
+	completion := FolderCompletion{}
+
+	query := url.Values{}
+	query.Set("device", deviceID)
+	query.Set("folder", folderID)
+
+	st.logger.V(4).Info("Fetching folder completion", "device", deviceID, "folder", folderID)
+	err := st.client().Do(ctx, http.MethodGet, "/rest/db/completion", query, nil, &completion)
+	return completion, err
+}
+
+// FetchAllPeerCompletion fetches FolderCompletion for every non-self device
+// against every configured folder, and stores the result on PeerCompletion
+// keyed by device ID. A device sharing more than one folder is recorded
+// under the completion of the last folder fetched; Syncthing movers
+// currently only ever configure a single shared folder per peer.
+func (st *Syncthing) FetchAllPeerCompletion(ctx context.Context) error {
+	// Snapshot the device/folder list and self ID under lock, then run the
+	// (potentially slow) HTTP requests without holding it, so this doesn't
+	// block the other services in the supervisor for the duration of the
+	// fetch.
+	st.mu.Lock()
+	if st.SystemStatus == nil {
+		// Running on its own timer in the supervisor model, with no guarantee
+		// FetchSyncthingSystemStatus has populated this yet.
+		st.mu.Unlock()
+		st.logger.V(4).Info("Skipping peer completion fetch: system status not yet populated")
+		return nil
+	}
+	myID := st.SystemStatus.MyID
+	devices := append([]SyncthingDevice{}, st.Config.Devices...)
+	folders := append([]SyncthingFolder{}, st.Config.Folders...)
+	st.mu.Unlock()
+
+	completion := map[string]FolderCompletion{}
+
+	for _, device := range devices {
+		if device.DeviceID == myID {
+			continue
+		}
+		for _, folder := range folders {
+			fc, err := st.FetchFolderCompletion(ctx, device.DeviceID, folder.ID)
+			if err != nil {
+				st.logger.V(4).Error(err, "Failed to fetch folder completion",
+					"device", device.DeviceID, "folder", folder.ID)
+				continue
+			}
+			completion[device.DeviceID] = fc
+		}
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.PeerCompletion = completion
+	return nil
+}
+
+// PeerStatus builds the v1alpha1 peer status list for peerList, combining
+// connection state with the most recently fetched FolderCompletion for each
+// peer.
+func (st *Syncthing) PeerStatus(peerList []v1alpha1.SyncthingPeer) []v1alpha1.SyncthingPeerStatus {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	statuses := make([]v1alpha1.SyncthingPeerStatus, 0, len(peerList))
+
+	for _, peer := range peerList {
+		status := v1alpha1.SyncthingPeerStatus{
+			ID:      peer.ID,
+			Address: peer.Address,
+		}
+		if st.SystemConnections != nil {
+			if conn, ok := st.SystemConnections.Connections[peer.ID]; ok {
+				status.Connected = conn.Connected
+			}
+		}
+		if fc, ok := st.PeerCompletion[peer.ID]; ok {
+			status.Completion = fc.Completion
+			status.NeedBytes = fc.NeedBytes
+			status.Sequence = fc.Sequence
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
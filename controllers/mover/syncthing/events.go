@@ -0,0 +1,157 @@
+package syncthing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// EventType identifies the "type" field of an entry returned by Syncthing's
+// /rest/events endpoint.
+type EventType string
+
+const (
+	EventDeviceConnected    EventType = "DeviceConnected"
+	EventDeviceDisconnected EventType = "DeviceDisconnected"
+	EventConfigSaved        EventType = "ConfigSaved"
+	EventFolderCompletion   EventType = "FolderCompletion"
+	EventLocalIndexUpdated  EventType = "LocalIndexUpdated"
+)
+
+// Event is a single entry returned by GET /rest/events.
+type Event struct {
+	ID       int64           `json:"id"`
+	GlobalID int64           `json:"globalID"`
+	Time     time.Time       `json:"time"`
+	Type     EventType       `json:"type"`
+	Data     json.RawMessage `json:"data"`
+}
+
+const (
+	// eventLongPollTimeout is sent to Syncthing as the "timeout=" query
+	// param, telling it how long to hold the request open waiting for a
+	// new event before responding with an empty array.
+	eventLongPollTimeout = 60 * time.Second
+	// fallbackFetchInterval bounds how stale our view of Syncthing can get
+	// if the event stream can't be established or silently stalls.
+	fallbackFetchInterval = 10 * time.Minute
+
+	eventReconnectInitialBackoff = time.Second
+	eventReconnectMaxBackoff     = 10 * time.Minute
+)
+
+// SubscribeEvents starts a background long-poll against Syncthing's
+// /rest/events, restricted to the given event types, and returns a channel
+// of decoded Events. The channel is closed once ctx is canceled. Failed
+// requests are retried with jittered exponential backoff. A full
+// FetchLatestInfo is also performed on a longer, fixed interval as a
+// fallback in case events are missed or the stream can't be established,
+// so callers no longer need to poll on every reconcile.
+func (st *Syncthing) SubscribeEvents(ctx context.Context, types []string) (<-chan Event, error) {
+	events := make(chan Event)
+
+	go st.pollEvents(ctx, types, events)
+	go st.fallbackFetchLoop(ctx)
+
+	return events, nil
+}
+
+// pollEvents repeatedly long-polls /rest/events, tracking the highest event
+// ID seen so the next request only returns events that occurred since.
+func (st *Syncthing) pollEvents(ctx context.Context, types []string, events chan<- Event) {
+	defer close(events)
+
+	var since int64
+	backoff := eventReconnectInitialBackoff
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		batch, err := st.fetchEvents(ctx, since, types)
+		if err != nil {
+			st.logger.V(4).Error(err, "Event long-poll failed, reconnecting", "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(jitter(backoff)):
+			}
+			backoff = doubled(backoff, eventReconnectMaxBackoff)
+			continue
+		}
+		backoff = eventReconnectInitialBackoff
+
+		for _, event := range batch {
+			since = event.ID
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// fetchEvents performs a single long-poll request for events with id >
+// since, optionally restricted to the given event types. It derives its own
+// timeout from ctx, long enough to cover eventLongPollTimeout, since the
+// long-poll itself can legitimately take that long to respond.
+func (st *Syncthing) fetchEvents(ctx context.Context, since int64, types []string) ([]Event, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, eventLongPollTimeout+5*time.Second)
+	defer cancel()
+
+	query := url.Values{}
+	query.Set("since", fmt.Sprintf("%d", since))
+	query.Set("timeout", fmt.Sprintf("%d", int(eventLongPollTimeout.Seconds())))
+	if len(types) > 0 {
+		query.Set("events", strings.Join(types, ","))
+	}
+
+	batch := []Event{}
+	if err := st.client().Do(reqCtx, http.MethodGet, "/rest/events", query, nil, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// fallbackFetchLoop periodically calls FetchLatestInfo regardless of the
+// event stream's health, so reconciliation never stalls indefinitely.
+func (st *Syncthing) fallbackFetchLoop(ctx context.Context) {
+	ticker := time.NewTicker(fallbackFetchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := st.FetchLatestInfo(ctx); err != nil {
+				st.logger.V(4).Error(err, "Fallback full fetch failed")
+			}
+		}
+	}
+}
+
+// doubled returns d*2, capped at max.
+func doubled(d, max time.Duration) time.Duration {
+	d *= 2
+	if d > max {
+		return max
+	}
+	return d
+}
+
+// jitter returns a random duration in [d/2, d], to avoid every reconnect
+// attempt landing on Syncthing at the same moment.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,268 @@
+package syncthing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+// Service is a long-running child task managed by a Supervisor. Serve
+// should block until ctx is canceled, or until it hits an error it cannot
+// recover from; a nil return is treated as a graceful, non-restartable
+// exit.
+type Service interface {
+	Name() string
+	Serve(ctx context.Context) error
+}
+
+const (
+	restartInitialBackoff = time.Second
+	restartMaxBackoff     = 10 * time.Minute
+	restartStableAfter    = 60 * time.Second
+
+	degradedThreshold = 5
+	degradedWindow    = 2 * time.Minute
+)
+
+// Supervisor restarts a fixed set of child Services independently with
+// exponential backoff whenever one exits with an error, the same pattern
+// upstream Syncthing uses for its own connection service. It becomes the
+// single persistent background loop the Kubernetes reconciler inspects,
+// replacing the previous fetch-on-every-reconcile model.
+type Supervisor struct {
+	services   []Service
+	onDegraded func(service string, degraded bool)
+
+	mu       sync.Mutex
+	failures map[string][]time.Time
+}
+
+// NewSupervisor creates a Supervisor for the given child services.
+// onDegraded, if non-nil, is called whenever a service crosses (or recovers
+// from) the degradedThreshold failure count within degradedWindow.
+func NewSupervisor(onDegraded func(service string, degraded bool), services ...Service) *Supervisor {
+	return &Supervisor{
+		services:   services,
+		onDegraded: onDegraded,
+		failures:   map[string][]time.Time{},
+	}
+}
+
+// Serve runs every child service, restarting each independently, until ctx
+// is canceled.
+func (s *Supervisor) Serve(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, svc := range s.services {
+		wg.Add(1)
+		go func(svc Service) {
+			defer wg.Done()
+			s.superviseOne(ctx, svc)
+		}(svc)
+	}
+	wg.Wait()
+}
+
+func (s *Supervisor) superviseOne(ctx context.Context, svc Service) {
+	backoff := restartInitialBackoff
+	for {
+		start := time.Now()
+		stable := time.AfterFunc(restartStableAfter, func() { s.clearFailures(svc.Name()) })
+		err := svc.Serve(ctx)
+		stable.Stop()
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			return
+		}
+
+		s.recordFailure(svc.Name())
+		if time.Since(start) >= restartStableAfter {
+			backoff = restartInitialBackoff
+			s.clearFailures(svc.Name())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = doubled(backoff, restartMaxBackoff)
+	}
+}
+
+// recordFailure timestamps a failure of the named service and notifies
+// onDegraded when it crosses degradedThreshold failures within
+// degradedWindow. Recovery is reported by clearFailures, not here.
+func (s *Supervisor) recordFailure(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-degradedWindow)
+	recent := make([]time.Time, 0, len(s.failures[name])+1)
+	for _, t := range s.failures[name] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	wasDegraded := len(recent) >= degradedThreshold
+	recent = append(recent, now)
+	s.failures[name] = recent
+	isDegraded := len(recent) >= degradedThreshold
+
+	if s.onDegraded != nil && isDegraded != wasDegraded {
+		s.onDegraded(name, isDegraded)
+	}
+}
+
+// clearFailures is called once a restart of name has stayed up for
+// restartStableAfter. If name is Degraded, it drops name's recorded failure
+// history and notifies onDegraded that it has recovered; otherwise the
+// history is left as is, to age out of degradedWindow. Without this, a
+// service that recovers and stops failing would stay marked Degraded
+// forever, since nothing else ages out the window until another failure
+// arrives.
+func (s *Supervisor) clearFailures(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wasDegraded := len(s.failures[name]) >= degradedThreshold
+	if !wasDegraded {
+		return
+	}
+
+	delete(s.failures, name)
+	if s.onDegraded != nil {
+		s.onDegraded(name, false)
+	}
+}
+
+// apiHealthService periodically probes the Syncthing REST API to confirm
+// it's still reachable and responding.
+type apiHealthService struct {
+	st       *Syncthing
+	interval time.Duration
+}
+
+func (a *apiHealthService) Name() string { return "api-health" }
+
+func (a *apiHealthService) Serve(ctx context.Context) error {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.st.FetchSyncthingSystemStatus(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eventSubscriberService drains the Syncthing event stream, invoking
+// onEvent for each Event received, until the stream ends or ctx is
+// canceled.
+type eventSubscriberService struct {
+	st      *Syncthing
+	types   []string
+	onEvent func(Event)
+}
+
+func (e *eventSubscriberService) Name() string { return "event-subscriber" }
+
+func (e *eventSubscriberService) Serve(ctx context.Context) error {
+	events, err := e.st.SubscribeEvents(ctx, e.types)
+	if err != nil {
+		return err
+	}
+	for event := range events {
+		if e.onEvent != nil {
+			e.onEvent(event)
+		}
+	}
+	return nil
+}
+
+// completionFetcherService periodically refreshes per-peer folder
+// completion.
+type completionFetcherService struct {
+	st       *Syncthing
+	interval time.Duration
+}
+
+func (c *completionFetcherService) Name() string { return "completion-fetcher" }
+
+func (c *completionFetcherService) Serve(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := c.st.FetchAllPeerCompletion(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deviceReconcilerService pushes the desired peer list to Syncthing
+// whenever it diverges from the current device config.
+type deviceReconcilerService struct {
+	st       *Syncthing
+	peerList func() []v1alpha1.SyncthingPeer
+	interval time.Duration
+}
+
+func (d *deviceReconcilerService) Name() string { return "device-reconciler" }
+
+func (d *deviceReconcilerService) Serve(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			peers := d.peerList()
+			if !d.st.NeedsReconfigure(peers) {
+				continue
+			}
+			d.st.UpdateDevices(peers)
+			if err := d.st.UpdateSyncthingConfig(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NewDefaultSupervisor builds the standard Supervisor used by the Syncthing
+// mover: an API health probe, the event-stream subscriber, a periodic
+// completion fetcher, and the device-config reconciler. onEvent is invoked
+// for every event observed on the event stream; onDegraded is invoked
+// whenever a child service starts or stops being Degraded.
+func NewDefaultSupervisor(st *Syncthing, peerList func() []v1alpha1.SyncthingPeer,
+	onEvent func(Event), onDegraded func(service string, degraded bool)) *Supervisor {
+	return NewSupervisor(onDegraded,
+		&apiHealthService{st: st, interval: 30 * time.Second},
+		&eventSubscriberService{
+			st: st,
+			types: []string{
+				string(EventDeviceConnected),
+				string(EventDeviceDisconnected),
+				string(EventConfigSaved),
+				string(EventFolderCompletion),
+				string(EventLocalIndexUpdated),
+			},
+			onEvent: onEvent,
+		},
+		&completionFetcherService{st: st, interval: time.Minute},
+		&deviceReconcilerService{st: st, peerList: peerList, interval: 30 * time.Second},
+	)
+}
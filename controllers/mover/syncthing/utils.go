@@ -1,23 +1,22 @@
 package syncthing
 
 import (
-	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
-	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/backube/volsync/api/v1alpha1"
 )
 
 // UpdateDevices Updates the Syncthing's connected devices with the provided peerList.
 func (st *Syncthing) UpdateDevices(peerList []v1alpha1.SyncthingPeer) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
 	st.logger.V(4).Info("Updating devices", "peerlist", peerList)
 
 	// update syncthing config based on the provided peerlist
@@ -34,7 +33,7 @@ func (st *Syncthing) UpdateDevices(peerList []v1alpha1.SyncthingPeer) {
 	for _, device := range peerList {
 		stDeviceToAdd := SyncthingDevice{
 			DeviceID:   device.ID,
-			Addresses:  []string{device.Address},
+			Addresses:  []string{addressOrDynamic(device.Address)},
 			Introducer: device.Introducer,
 		}
 		st.logger.V(4).Info("Adding device: %+v\n", stDeviceToAdd)
@@ -70,6 +69,9 @@ func (st *Syncthing) updateFolders() {
 
 // NeedsReconfigure Determines whether the given nodeList differs from Syncthing's internal devices.
 func (st *Syncthing) NeedsReconfigure(nodeList []v1alpha1.SyncthingPeer) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
 	// check if the syncthing nodelist diverges from the current syncthing devices
 	var newDevices map[string]v1alpha1.SyncthingPeer = map[string]v1alpha1.SyncthingPeer{
 		// initialize the map with the self node
@@ -183,76 +185,89 @@ func (st *Syncthing) GetDeviceFromID(deviceID string) (SyncthingDevice, bool) {
 }
 
 // FetchLatestInfo Updates the Syncthing object with the latest data fetched from the Syncthing API.
-func (st *Syncthing) FetchLatestInfo() error {
-	if err := st.FetchSyncthingConfig(); err != nil {
+// ctx is typically scoped to a single reconcile or to the mover's shutdown, so a canceled reconcile
+// aborts any request still in flight.
+func (st *Syncthing) FetchLatestInfo(ctx context.Context) error {
+	if err := st.FetchSyncthingConfig(ctx); err != nil {
 		return err
 	}
-	if err := st.FetchSyncthingSystemStatus(); err != nil {
+	if err := st.FetchSyncthingSystemStatus(ctx); err != nil {
 		return err
 	}
-	if err := st.FetchConnectedStatus(); err != nil {
+	if err := st.FetchConnectedStatus(ctx); err != nil {
 		return err
 	}
 	return nil
 }
 
 // UpdateSyncthingConfig Updates the Syncthing config with the locally-stored config.
-func (st *Syncthing) UpdateSyncthingConfig() error {
+func (st *Syncthing) UpdateSyncthingConfig(ctx context.Context) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
 	// update the config
 	st.logger.V(4).Info("Updating Syncthing config")
-	_, err := st.jsonRequest("/rest/config", "PUT", st.Config)
+	st.applyDiscoveryOptions()
+	err := st.client().Do(ctx, http.MethodPut, "/rest/config", nil, st.Config, nil)
 	if err != nil {
 		st.logger.V(4).Error(err, "Failed to update Syncthing config")
 		return err
 	}
-	return err
+	return nil
 }
 
 // FetchSyncthingConfig fetches the Syncthing config and updates the config.
-func (st *Syncthing) FetchSyncthingConfig() error {
+func (st *Syncthing) FetchSyncthingConfig(ctx context.Context) error {
 	responseBody := &SyncthingConfig{
 		Devices: []SyncthingDevice{},
 		Folders: []SyncthingFolder{},
 	}
 	st.logger.V(4).Info("Fetching Syncthing config")
-	data, err := st.jsonRequest("/rest/config", "GET", nil)
-	if err != nil {
+	if err := st.client().Do(ctx, http.MethodGet, "/rest/config", nil, nil, responseBody); err != nil {
 		return err
 	}
-	err = json.Unmarshal(data, responseBody)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
 	st.Config = responseBody
-	return err
+	return nil
 }
 
 // FetchSyncthingSystemStatus fetches the Syncthing system status.
-func (st *Syncthing) FetchSyncthingSystemStatus() error {
+func (st *Syncthing) FetchSyncthingSystemStatus(ctx context.Context) error {
 	responseBody := &SystemStatus{}
 	st.logger.V(4).Info("Fetching Syncthing system status")
-	data, err := st.jsonRequest("/rest/system/status", "GET", nil)
-	if err != nil {
+	if err := st.client().Do(ctx, http.MethodGet, "/rest/system/status", nil, nil, responseBody); err != nil {
 		return err
 	}
-	// unmarshal the data into the responseBody
-	err = json.Unmarshal(data, responseBody)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
 	st.SystemStatus = responseBody
-	return err
+	return nil
 }
 
 // FetchConnectedStatus Fetches the connection status of the syncthing instance.
-func (st *Syncthing) FetchConnectedStatus() error {
+func (st *Syncthing) FetchConnectedStatus(ctx context.Context) error {
 	// updates the connected status if successful, else returns an error
 	responseBody := &SystemConnections{
 		Connections: map[string]ConnectionStats{},
 	}
 	st.logger.V(4).Info("Fetching Syncthing connected status")
-	data, err := st.jsonRequest("/rest/system/connections", "GET", nil)
-	if err != nil {
+	if err := st.client().Do(ctx, http.MethodGet, "/rest/system/connections", nil, nil, responseBody); err != nil {
 		return err
 	}
-	if err = json.Unmarshal(data, responseBody); err == nil {
-		st.SystemConnections = responseBody
-	}
-	return err
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.SystemConnections = responseBody
+	return nil
+}
+
+// client builds a new Client for requests to this Syncthing instance's
+// API; nothing is cached between calls.
+func (st *Syncthing) client() *Client {
+	return NewClient(st.APIConfig, st.logger)
 }
 
 // GetDeviceName Returns the name of the device with the given ID, if one is provided.
@@ -265,49 +280,6 @@ func (st *Syncthing) GetDeviceName(deviceID string) string {
 	return ""
 }
 
-// jsonRequest performs a request to the Syncthing API and returns the response body.
-//nolint:funlen,lll,unparam,unused
-func (st *Syncthing) jsonRequest(endpoint string, method string, requestBody interface{}) ([]byte, error) {
-	// marshal above json body into a string
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, err
-	}
-	// tostring the json body
-	body := io.Reader(bytes.NewReader(jsonBody))
-
-	// build new client if none exists
-	req, err := http.NewRequest(method, st.APIConfig.APIURL+endpoint, body)
-	if err != nil {
-		return nil, err
-	}
-
-	// set headers
-	headers, err := st.APIConfig.Headers()
-	if err != nil {
-		return nil, err
-	}
-	for key, value := range headers {
-		req.Header.Set(key, value)
-	}
-
-	// make an HTTPS POST request
-	if err != nil {
-		return nil, err
-	}
-	resp, err := st.APIConfig.Client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		return nil, errors.New("HTTP status code is not 200")
-	}
-
-	// read body into response
-	return ioutil.ReadAll(resp.Body)
-}
-
 // Headers Returns a map containing the necessary headers for Syncthing API requests.
 // When no API Key is provided, an error is returned.
 func (api *APIConfig) Headers() (map[string]string, error) {
@@ -342,9 +314,12 @@ func (api *APIConfig) BuildTLSClient() *http.Client {
 	tr := &http.Transport{
 		TLSClientConfig: tlsConfig,
 	}
+	// No Timeout here: it would cap the whole request, including body
+	// read, cutting off long-poll calls (e.g. /rest/events) no matter
+	// how long their context allows. Callers govern duration via the
+	// context passed to Client.Do instead.
 	client := &http.Client{
 		Transport: tr,
-		Timeout:   time.Second * 5,
 	}
 	return client
 }
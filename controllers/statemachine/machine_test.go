@@ -217,6 +217,41 @@ var _ = Describe("missedDeadline", func() {
 	})
 })
 
+var _ = Describe("verifying peer completion", func() {
+	It("will verify until peers catch up, then proceeds to cleanup", func() {
+		m := newFakeMachine()
+		// Force verifying state, the way synchronizingState would once the
+		// local mover reports Complete().
+		Expect(transitionToSynchronizing(m, logger)).To(Succeed())
+		Expect(transitionToVerifying(m, logger)).To(Succeed())
+		Expect(currentState(m)).To(Equal(verifyingState))
+		Expect(apimeta.IsStatusConditionFalse(m.Cond, volsyncv1alpha1.ConditionSynchronizing)).To(BeTrue())
+		Expect(apimeta.IsStatusConditionTrue(m.Cond, volsyncv1alpha1.ConditionVerifying)).To(BeTrue())
+		Expect(apimeta.FindStatusCondition(m.Cond,
+			volsyncv1alpha1.ConditionVerifying).Reason).To(Equal(volsyncv1alpha1.SynchronizingReasonVerifying))
+
+		// Peers haven't caught up yet, so we stay in verifyingState.
+		m.VerifyResult = mover.InProgress()
+		_, err := Run(ctx, m, logger)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(currentState(m)).To(Equal(verifyingState))
+
+		m.VerifyError = fmt.Errorf("error")
+		_, err = Run(ctx, m, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(currentState(m)).To(Equal(verifyingState))
+		Expect(apimeta.IsStatusConditionFalse(m.Cond, volsyncv1alpha1.ConditionVerifying)).To(BeTrue())
+		Expect(apimeta.FindStatusCondition(m.Cond,
+			volsyncv1alpha1.ConditionVerifying).Reason).To(Equal(volsyncv1alpha1.SynchronizingReasonError))
+
+		// All peers (or their per-peer timeouts) caught up, so we move on.
+		m.VerifyResult, m.VerifyError = mover.Complete(), nil
+		_, err = Run(ctx, m, logger)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(currentState(m)).To(Equal(cleaningUpState))
+	})
+})
+
 var _ = When("the trigger is schedule-based", func() {
 	It("returns an error if the cronspec is invalid", func() {
 		m := newFakeMachine()
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package statemachine
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// verifyingState sits between synchronizingState and cleaningUpState: the
+// local mover reported Complete(), but for movers like Syncthing that
+// doesn't mean peers have received the data yet. It stays active until
+// Machine.VerifySynced reports Complete() (or a peer's per-peer timeout
+// elapses), then advances to cleaningUpState either way.
+//
+// transitionToVerifying mirrors transitionToCleaningUp: it flips
+// ConditionSynchronizing off and marks us as actively verifying via
+// volsyncv1alpha1.ConditionVerifying, with reason SynchronizingReasonVerifying.
+func transitionToVerifying(m Machine, l logr.Logger) error {
+	l.V(1).Info("synchronization complete locally; verifying peers received it")
+	apimeta.SetStatusCondition(m.Conditions(), metav1Condition(
+		volsyncv1alpha1.ConditionSynchronizing, false, volsyncv1alpha1.SynchronizingReasonVerifying,
+		"Awaiting confirmation that peers have received the synchronized data"))
+	apimeta.SetStatusCondition(m.Conditions(), metav1Condition(
+		volsyncv1alpha1.ConditionVerifying, true, volsyncv1alpha1.SynchronizingReasonVerifying,
+		"Waiting for remote peer completion to catch up with the local sync"))
+	return setState(m, verifyingState)
+}
+
+// enterVerifying runs while in verifyingState: it asks the Machine to verify
+// that peers have caught up, and advances to cleaningUpState once that
+// either succeeds or a peer is marked Stale after timing out. A real error
+// (as opposed to in-progress verification) is surfaced and retried on the
+// next reconcile, the same way synchronizingState and cleaningUpState treat
+// mover errors.
+func enterVerifying(ctx context.Context, m Machine, l logr.Logger) error {
+	result, err := m.VerifySynced(ctx)
+	if err != nil {
+		apimeta.SetStatusCondition(m.Conditions(), metav1Condition(
+			volsyncv1alpha1.ConditionVerifying, false, volsyncv1alpha1.SynchronizingReasonError, err.Error()))
+		return err
+	}
+	if !result.Completed {
+		// Still waiting on one or more peers (or their timeouts); stay put.
+		return nil
+	}
+
+	apimeta.SetStatusCondition(m.Conditions(), metav1Condition(
+		volsyncv1alpha1.ConditionVerifying, false, volsyncv1alpha1.SynchronizingReasonVerifying,
+		"Peer verification complete"))
+	return transitionToCleaningUp(m, l)
+}